@@ -0,0 +1,76 @@
+// Package api holds the wire types shared between openstorage's volume
+// drivers and everything that drives them (the CSI front-end, the plugin
+// protocol, osdconfig, and so on).
+package api
+
+// VolumeID uniquely identifies a volume.
+type VolumeID string
+
+// SnapID uniquely identifies a snapshot.
+type SnapID string
+
+// Labels are free-form key/value tags attached to a volume, snapshot, or
+// locator.
+type Labels map[string]string
+
+// Format is the filesystem a volume is formatted with.
+type Format string
+
+// VolumeLocator identifies a volume by name/labels, independent of its spec.
+type VolumeLocator struct {
+	Name         string
+	VolumeLabels Labels
+}
+
+// VolumeSpec describes how a volume should be created and mounted.
+type VolumeSpec struct {
+	Size   uint64
+	Format Format
+	Labels Labels
+}
+
+// CreateOptions carries additional, non-spec instructions for Create, such
+// as a template to seed the new volume from.
+type CreateOptions struct {
+	// Seed is a URI (docker://, git://, file://, s3://, ...) resolved by
+	// pkg/seed to populate the volume before it is handed back to the
+	// caller.
+	Seed string
+}
+
+// Volume is the read-only view of a volume returned by Inspect/Enumerate.
+type Volume struct {
+	ID         VolumeID
+	Locator    VolumeLocator
+	Spec       *VolumeSpec
+	AttachPath string
+}
+
+// VolumeStats reports capacity and driver-specific performance counters for
+// a single volume.
+type VolumeStats struct {
+	Total uint64
+	Used  uint64
+	Free  uint64
+
+	// NFSOpCounts, NFSRTTMillis and NFSExecMillis are populated by NFS-backed
+	// drivers from /proc/self/mountstats' per-op statistics table, keyed by
+	// NFS operation name (e.g. "READ", "WRITE"). They are left nil by
+	// drivers that aren't NFS-backed.
+	NFSOpCounts   map[string]uint64
+	NFSRTTMillis  map[string]uint64
+	NFSExecMillis map[string]uint64
+}
+
+// VolumeAlerts carries any outstanding alerts raised against a volume.
+type VolumeAlerts struct {
+	Alerts []string
+}
+
+// VolumeSnap is the read-only view of a snapshot returned by
+// SnapInspect/SnapEnumerate.
+type VolumeSnap struct {
+	ID       SnapID
+	VolumeID VolumeID
+	Labels   Labels
+}
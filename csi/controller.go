@@ -0,0 +1,150 @@
+package csi
+
+import (
+	"context"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+
+	"github.com/libopenstorage/openstorage/api"
+)
+
+func (s *Server) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
+	types := []csi.ControllerServiceCapability_RPC_Type{
+		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
+		csi.ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+	}
+
+	caps := make([]*csi.ControllerServiceCapability, 0, len(types))
+	for _, t := range types {
+		caps = append(caps, &csi.ControllerServiceCapability{
+			Type: &csi.ControllerServiceCapability_Rpc{
+				Rpc: &csi.ControllerServiceCapability_RPC{Type: t},
+			},
+		})
+	}
+	return &csi.ControllerGetCapabilitiesResponse{Capabilities: caps}, nil
+}
+
+func (s *Server) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+	locator := api.VolumeLocator{Name: req.GetName()}
+	spec := &api.VolumeSpec{}
+	if cap := req.GetCapacityRange(); cap != nil {
+		spec.Size = uint64(cap.GetRequiredBytes())
+	}
+
+	volumeID, err := s.driver.Create(locator, &api.CreateOptions{}, spec)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &csi.CreateVolumeResponse{
+		Volume: &csi.Volume{
+			VolumeId:      string(volumeID),
+			CapacityBytes: int64(spec.Size),
+		},
+	}, nil
+}
+
+func (s *Server) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
+	if err := s.driver.Delete(api.VolumeID(req.GetVolumeId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &csi.DeleteVolumeResponse{}, nil
+}
+
+func (s *Server) ControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error) {
+	path, err := s.driver.Attach(api.VolumeID(req.GetVolumeId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &csi.ControllerPublishVolumeResponse{
+		PublishContext: map[string]string{"devicePath": path},
+	}, nil
+}
+
+func (s *Server) ControllerUnpublishVolume(ctx context.Context, req *csi.ControllerUnpublishVolumeRequest) (*csi.ControllerUnpublishVolumeResponse, error) {
+	if err := s.driver.Detach(api.VolumeID(req.GetVolumeId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &csi.ControllerUnpublishVolumeResponse{}, nil
+}
+
+func (s *Server) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
+	return &csi.ValidateVolumeCapabilitiesResponse{
+		Confirmed: &csi.ValidateVolumeCapabilitiesResponse_Confirmed{
+			VolumeContext:      req.GetVolumeContext(),
+			VolumeCapabilities: req.GetVolumeCapabilities(),
+			Parameters:         req.GetParameters(),
+		},
+	}, nil
+}
+
+func (s *Server) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	vols, err := s.driver.Enumerate(api.VolumeLocator{}, nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	entries := make([]*csi.ListVolumesResponse_Entry, 0, len(vols))
+	for _, v := range vols {
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: &csi.Volume{VolumeId: string(v.ID)},
+		})
+	}
+	return &csi.ListVolumesResponse{Entries: entries}, nil
+}
+
+func (s *Server) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
+	return &csi.GetCapacityResponse{}, nil
+}
+
+func (s *Server) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
+	snapID, err := s.driver.Snapshot(api.VolumeID(req.GetSourceVolumeId()), nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &csi.CreateSnapshotResponse{
+		Snapshot: &csi.Snapshot{
+			SnapshotId:     string(snapID),
+			SourceVolumeId: req.GetSourceVolumeId(),
+			ReadyToUse:     true,
+		},
+	}, nil
+}
+
+func (s *Server) DeleteSnapshot(ctx context.Context, req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
+	if err := s.driver.SnapDelete(api.SnapID(req.GetSnapshotId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &csi.DeleteSnapshotResponse{}, nil
+}
+
+func (s *Server) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	snaps, err := s.driver.SnapEnumerate(api.VolumeLocator{}, nil)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	entries := make([]*csi.ListSnapshotsResponse_Entry, 0, len(snaps))
+	for _, snap := range snaps {
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{
+			Snapshot: &csi.Snapshot{
+				SnapshotId:     string(snap.ID),
+				SourceVolumeId: string(snap.VolumeID),
+				ReadyToUse:     true,
+			},
+		})
+	}
+	return &csi.ListSnapshotsResponse{Entries: entries}, nil
+}
+
+func (s *Server) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
+	return nil, toStatus(errNotImplemented("ControllerExpandVolume"))
+}
+
+func (s *Server) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	return nil, toStatus(errNotImplemented("ControllerGetVolume"))
+}
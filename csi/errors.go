@@ -0,0 +1,31 @@
+package csi
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/libopenstorage/openstorage/volume"
+)
+
+// toStatus translates a VolumeDriver error into the CSI error a given RPC is
+// expected to return. volume.ErrNotSupported becomes Unimplemented so the
+// CA/kubelet sidecars correctly fall back to not advertising that
+// capability, instead of retrying a call that can never succeed.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == volume.ErrNotSupported {
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// errNotImplemented is used for CSI RPCs that don't have a VolumeDriver
+// counterpart at all (as opposed to one that exists but returned
+// volume.ErrNotSupported).
+func errNotImplemented(rpc string) error {
+	return fmt.Errorf("%s is not implemented by this driver", rpc)
+}
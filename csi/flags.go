@@ -0,0 +1,15 @@
+package csi
+
+import "flag"
+
+// RegisterFlags adds the --provide-node-service/--provide-controller-service
+// split to fs, letting the same openstorage binary be deployed as
+// controller-only or node-only pods. Call this from main() before
+// flag.Parse() and pass the results into Config.
+func RegisterFlags(fs *flag.FlagSet) (provideController, provideNode *bool) {
+	provideController = fs.Bool("provide-controller-service", true,
+		"Run the CSI Controller service in this process")
+	provideNode = fs.Bool("provide-node-service", true,
+		"Run the CSI Node service in this process")
+	return
+}
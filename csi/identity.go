@@ -0,0 +1,40 @@
+package csi
+
+import (
+	"context"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+)
+
+const pluginVersion = "0.1.0"
+
+func (s *Server) GetPluginInfo(ctx context.Context, req *csi.GetPluginInfoRequest) (*csi.GetPluginInfoResponse, error) {
+	return &csi.GetPluginInfoResponse{
+		Name:          "com.openstorage." + s.config.DriverName,
+		VendorVersion: pluginVersion,
+	}, nil
+}
+
+func (s *Server) GetPluginCapabilities(ctx context.Context, req *csi.GetPluginCapabilitiesRequest) (*csi.GetPluginCapabilitiesResponse, error) {
+	caps := []*csi.PluginCapability{}
+	if s.config.ProvideControllerService {
+		caps = append(caps, newPluginServiceCapability(csi.PluginCapability_Service_CONTROLLER_SERVICE))
+	}
+	return &csi.GetPluginCapabilitiesResponse{Capabilities: caps}, nil
+}
+
+// Probe always reports healthy. The driver has no liveness call; String() is
+// called on it, but that does not contact the driver's backend, so an
+// unreachable driver is not detected here.
+func (s *Server) Probe(ctx context.Context, req *csi.ProbeRequest) (*csi.ProbeResponse, error) {
+	_ = s.driver.String()
+	return &csi.ProbeResponse{}, nil
+}
+
+func newPluginServiceCapability(t csi.PluginCapability_Service_Type) *csi.PluginCapability {
+	return &csi.PluginCapability{
+		Type: &csi.PluginCapability_Service_{
+			Service: &csi.PluginCapability_Service{Type: t},
+		},
+	}
+}
@@ -0,0 +1,92 @@
+// Package csi exposes any driver registered via volume.Register as a CSI 1.x
+// Identity/Controller/Node server over a Unix-domain socket, so openstorage
+// can be deployed straight into Kubernetes without a bespoke provisioner.
+package csi
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+
+	"github.com/libopenstorage/openstorage/volume"
+)
+
+// Config controls which CSI services a given process instance advertises,
+// so the same binary can be deployed as controller-only or node-only pods.
+type Config struct {
+	// DriverName is the name a driver was registered under via
+	// volume.Register (e.g. nfs.Name).
+	DriverName string
+
+	// NodeID identifies the Kubernetes node this process is running on;
+	// only needed when ProvideNodeService is set.
+	NodeID string
+
+	// Net/Address are passed to net.Listen, e.g. ("unix",
+	// "/run/openstorage/csi.sock").
+	Net     string
+	Address string
+
+	ProvideControllerService bool
+	ProvideNodeService       bool
+}
+
+// Server is the gRPC server backing the CSI Identity/Controller/Node
+// services for a single registered volume.VolumeDriver.
+type Server struct {
+	config Config
+	driver volume.VolumeDriver
+	grpc   *grpc.Server
+}
+
+// New looks up the instance of the driver named in config.DriverName (one
+// that volume.New has already constructed) and wires it up behind the
+// requested CSI services.
+func New(config Config) (*Server, error) {
+	driver, err := volume.Get(config.DriverName)
+	if err != nil {
+		return nil, fmt.Errorf("csi: driver %q has no constructed instance: %v", config.DriverName, err)
+	}
+
+	s := &Server{
+		config: config,
+		driver: driver,
+		grpc:   grpc.NewServer(),
+	}
+
+	csi.RegisterIdentityServer(s.grpc, s)
+	if config.ProvideControllerService {
+		csi.RegisterControllerServer(s.grpc, s)
+	}
+	if config.ProvideNodeService {
+		csi.RegisterNodeServer(s.grpc, s)
+	}
+
+	return s, nil
+}
+
+// Start listens on config.Net/config.Address and serves until Stop is
+// called. Any stale socket file at Address is removed first, mirroring how
+// other Unix-socket servers in this tree are started.
+func (s *Server) Start() error {
+	if s.config.Net == "unix" {
+		if _, err := os.Stat(s.config.Address); err == nil {
+			os.Remove(s.config.Address)
+		}
+	}
+
+	ln, err := net.Listen(s.config.Net, s.config.Address)
+	if err != nil {
+		return err
+	}
+
+	return s.grpc.Serve(ln)
+}
+
+// Stop gracefully stops the gRPC server.
+func (s *Server) Stop() {
+	s.grpc.GracefulStop()
+}
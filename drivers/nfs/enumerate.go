@@ -0,0 +1,74 @@
+package nfs
+
+import (
+	"strings"
+
+	"github.com/libopenstorage/openstorage/api"
+)
+
+// toAPIVolume translates a persisted awsVolume into the api.Volume shape
+// callers expect from Inspect/Enumerate.
+func toAPIVolume(volumeID string, v *awsVolume) api.Volume {
+	vol := api.Volume{
+		ID:   api.VolumeID(volumeID),
+		Spec: &v.Spec,
+	}
+
+	if v.Mounted && v.Mountpath != "" {
+		vol.AttachPath = v.Mountpath
+	}
+
+	return vol
+}
+
+// Inspect looks up each requested volume directly by ID, the same kvdb key
+// every other per-volume method uses.
+func (self *nfsProvider) Inspect(volumeIDs []api.VolumeID) ([]api.Volume, error) {
+	vols := make([]api.Volume, 0, len(volumeIDs))
+	for _, id := range volumeIDs {
+		v, err := self.get(string(id))
+		if err != nil {
+			return nil, err
+		}
+		vols = append(vols, toAPIVolume(string(id), v))
+	}
+	return vols, nil
+}
+
+// Enumerate scans every volume record under NfsDBKey and filters client-side
+// by locator name/labels, since kvdb only gives us a prefix scan.
+func (self *nfsProvider) Enumerate(locator api.VolumeLocator, labels api.Labels) ([]api.Volume, error) {
+	kvps, err := self.db.Enumerate(NfsDBKey + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	vols := make([]api.Volume, 0, len(kvps))
+	for _, kvp := range kvps {
+		volumeID := strings.TrimPrefix(kvp.Key, NfsDBKey+"/")
+		if strings.HasPrefix(volumeID, "snap/") {
+			continue
+		}
+
+		// Re-fetch through get() rather than decoding kvp.Value ourselves,
+		// so Enumerate stays in lock-step with however kvdb serializes an
+		// awsVolume for every other per-volume method in this package.
+		v, err := self.get(volumeID)
+		if err != nil {
+			return nil, err
+		}
+
+		if locator.Name != "" && locator.Name != v.Locator.Name {
+			continue
+		}
+		if !matchesLabels(v.Locator.VolumeLabels, locator.VolumeLabels) {
+			continue
+		}
+		if !matchesLabels(v.Spec.Labels, labels) {
+			continue
+		}
+
+		vols = append(vols, toAPIVolume(volumeID, v))
+	}
+	return vols, nil
+}
@@ -1,6 +1,7 @@
 package nfs
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/libopenstorage/kvdb"
 	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/openstorage/pkg/seed"
 	"github.com/libopenstorage/openstorage/volume"
 )
 
@@ -21,14 +23,17 @@ var (
 	devMinor int32
 )
 
-// This data is persisted in a DB.
+// This data is persisted in a DB. Fields are exported so the kvdb backend's
+// encoding/json-based Put/GetVal round-trips them -- an unexported field
+// would be silently dropped on every read.
 type awsVolume struct {
-	spec      api.VolumeSpec
-	formatted bool
-	attached  bool
-	mounted   bool
-	device    string
-	mountpath string
+	Locator   api.VolumeLocator
+	Spec      api.VolumeSpec
+	Formatted bool
+	Attached  bool
+	Mounted   bool
+	Device    string
+	Mountpath string
 }
 
 // Implements the open storage volume interface.
@@ -74,6 +79,11 @@ func Init(params volume.DriverParams) (volume.VolumeDriver, error) {
 	}
 
 	fmt.Printf("NFS initialized and driver mounted at %s.", inst.mntPath)
+
+	if err := inst.Refresh(); err != nil {
+		fmt.Printf("NFS driver: refresh on startup failed: %v\n", err)
+	}
+
 	return inst, nil
 }
 
@@ -99,6 +109,13 @@ func (self *nfsProvider) String() string {
 	return Name
 }
 
+// devicePath is the on-disk directory a volume ID is backed by. Every
+// method that creates or restores a volume directory goes through this so
+// Create and RestoreSnapshot never drift apart.
+func (self *nfsProvider) devicePath(volumeID string) string {
+	return self.mntPath + "/" + volumeID
+}
+
 func (self *nfsProvider) Create(l api.VolumeLocator, opt *api.CreateOptions, spec *api.VolumeSpec) (api.VolumeID, error) {
 	out, err := exec.Command("uuidgen").Output()
 	if err != nil {
@@ -107,21 +124,30 @@ func (self *nfsProvider) Create(l api.VolumeLocator, opt *api.CreateOptions, spe
 	volumeID := string(out)
 	volumeID = strings.TrimSuffix(volumeID, "\n")
 
+	device := self.devicePath(volumeID)
+
 	// Create a directory on the NFS server with this UUID.
-	err = os.Mkdir(self.mntPath+volumeID, 0744)
+	err = os.Mkdir(device, 0744)
 	if err != nil {
 		return "", err
 	}
 
+	if opt != nil && opt.Seed != "" {
+		if err := seed.Populate(device, opt.Seed, l.VolumeLabels, spec.Labels); err != nil {
+			os.RemoveAll(device)
+			return "", err
+		}
+	}
+
 	// Persist the volume spec.  We use this for all subsequent operations on
 	// this volume ID.
-	err = self.put(volumeID, &awsVolume{device: self.mntPath + volumeID, spec: *spec})
-
-	return api.VolumeID(volumeID), err
-}
+	err = self.put(volumeID, &awsVolume{Device: device, Spec: *spec, Locator: l})
+	if err != nil {
+		os.RemoveAll(device)
+		return "", err
+	}
 
-func (self *nfsProvider) Inspect(volumeIDs []api.VolumeID) ([]api.Volume, error) {
-	return nil, nil
+	return api.VolumeID(volumeID), nil
 }
 
 func (self *nfsProvider) Delete(volumeID api.VolumeID) error {
@@ -131,7 +157,7 @@ func (self *nfsProvider) Delete(volumeID api.VolumeID) error {
 	}
 
 	// Delete the directory on the nfs server.
-	err = os.Remove(v.device)
+	err = os.Remove(v.Device)
 	if err != nil {
 		return err
 	}
@@ -141,47 +167,23 @@ func (self *nfsProvider) Delete(volumeID api.VolumeID) error {
 	return nil
 }
 
-func (self *nfsProvider) Snapshot(volumeID api.VolumeID, labels api.Labels) (api.SnapID, error) {
-	return "", volume.ErrNotSupported
-}
-
-func (self *nfsProvider) SnapDelete(snapID api.SnapID) error {
-	return volume.ErrNotSupported
-}
-
-func (self *nfsProvider) SnapInspect(snapID []api.SnapID) ([]api.VolumeSnap, error) {
-	return []api.VolumeSnap{}, volume.ErrNotSupported
-}
-
-func (self *nfsProvider) Stats(volumeID api.VolumeID) (api.VolumeStats, error) {
-	return api.VolumeStats{}, volume.ErrNotSupported
-}
-
 func (self *nfsProvider) Alerts(volumeID api.VolumeID) (api.VolumeAlerts, error) {
 	return api.VolumeAlerts{}, volume.ErrNotSupported
 }
 
-func (self *nfsProvider) Enumerate(locator api.VolumeLocator, labels api.Labels) ([]api.Volume, error) {
-	return nil, volume.ErrNotSupported
-}
-
-func (self *nfsProvider) SnapEnumerate(locator api.VolumeLocator, labels api.Labels) ([]api.VolumeSnap, error) {
-	return nil, volume.ErrNotSupported
-}
-
 func (self *nfsProvider) Mount(volumeID api.VolumeID, mountpath string) error {
 	v, err := self.get(string(volumeID))
 	if err != nil {
 		return err
 	}
 
-	err = syscall.Mount(v.device, mountpath, string(v.spec.Format), 0, "")
+	err = syscall.Mount(v.Device, mountpath, string(v.Spec.Format), 0, "")
 	if err != nil {
 		return err
 	}
 
-	v.mountpath = mountpath
-	v.mounted = true
+	v.Mountpath = mountpath
+	v.Mounted = true
 	err = self.put(string(volumeID), v)
 
 	return err
@@ -193,13 +195,13 @@ func (self *nfsProvider) Unmount(volumeID api.VolumeID, mountpath string) error
 		return err
 	}
 
-	err = syscall.Unmount(v.mountpath, 0)
+	err = syscall.Unmount(v.Mountpath, 0)
 	if err != nil {
 		return err
 	}
 
-	v.mountpath = ""
-	v.mounted = false
+	v.Mountpath = ""
+	v.Mounted = false
 	err = self.put(string(volumeID), v)
 
 	return err
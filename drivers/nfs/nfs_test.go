@@ -0,0 +1,83 @@
+package nfs
+
+import (
+	"testing"
+
+	"github.com/libopenstorage/kvdb/mem"
+	"github.com/libopenstorage/openstorage/api"
+)
+
+func newTestProvider(t *testing.T) *nfsProvider {
+	// Domain is left empty: every key this package uses already carries the
+	// full NfsDBKey/... prefix itself, so a non-empty domain here would
+	// double it up.
+	kv, err := mem.New("", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create in-memory kvdb: %v", err)
+	}
+	return &nfsProvider{db: kv, mntPath: "/tmp/nfs-test"}
+}
+
+func TestInspect(t *testing.T) {
+	p := newTestProvider(t)
+
+	spec := api.VolumeSpec{}
+	if err := p.put("vol1", &awsVolume{Device: "/tmp/nfs-test/vol1", Spec: spec}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	vols, err := p.Inspect([]api.VolumeID{"vol1"})
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if len(vols) != 1 {
+		t.Fatalf("expected 1 volume, got %d", len(vols))
+	}
+	if vols[0].ID != "vol1" {
+		t.Errorf("expected ID vol1, got %v", vols[0].ID)
+	}
+}
+
+func TestEnumerateFiltersByName(t *testing.T) {
+	p := newTestProvider(t)
+
+	if err := p.put("vol1", &awsVolume{
+		Device:  "/tmp/nfs-test/vol1",
+		Locator: api.VolumeLocator{Name: "db"},
+	}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if err := p.put("vol2", &awsVolume{
+		Device:  "/tmp/nfs-test/vol2",
+		Locator: api.VolumeLocator{Name: "cache"},
+	}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+
+	vols, err := p.Enumerate(api.VolumeLocator{Name: "db"}, nil)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+	if len(vols) != 1 || vols[0].ID != "vol1" {
+		t.Fatalf("expected only vol1, got %+v", vols)
+	}
+}
+
+func TestEnumerateSkipsSnapshotRecords(t *testing.T) {
+	p := newTestProvider(t)
+
+	if err := p.put("vol1", &awsVolume{Device: "/tmp/nfs-test/vol1"}); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	if err := p.putSnap(&snapRecord{ID: "snap1", VolumeID: "vol1"}); err != nil {
+		t.Fatalf("putSnap failed: %v", err)
+	}
+
+	vols, err := p.Enumerate(api.VolumeLocator{}, nil)
+	if err != nil {
+		t.Fatalf("Enumerate failed: %v", err)
+	}
+	if len(vols) != 1 {
+		t.Fatalf("expected 1 volume, got %d: %+v", len(vols), vols)
+	}
+}
@@ -0,0 +1,102 @@
+package nfs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// mountedPaths returns the set of mountpoints the kernel currently has
+// mounted, read from /proc/self/mountinfo.
+func mountedPaths() (map[string]bool, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format: <id> <parent> <major:minor> <root> <mount point> ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		paths[fields[4]] = true
+	}
+	return paths, scanner.Err()
+}
+
+// Refresh reconciles kvdb's view of this driver's volumes with what the
+// kernel actually has mounted. It is run once at startup (after the NFS
+// export is bound) to recover from an openstorage crash or restart: stale
+// "mounted" flags are cleared, volumes whose backing directory has
+// disappeared are dropped from kvdb, and per-volume transient mount state is
+// reset so the next Mount call starts from a known-good baseline.
+func (self *nfsProvider) Refresh() error {
+	mounted, err := mountedPaths()
+	if err != nil {
+		return err
+	}
+
+	kvps, err := self.db.Enumerate(NfsDBKey + "/")
+	if err != nil {
+		return err
+	}
+
+	for _, kvp := range kvps {
+		volumeID := strings.TrimPrefix(kvp.Key, NfsDBKey+"/")
+		if strings.HasPrefix(volumeID, "snap/") {
+			// Snapshot records are checked against the Kopia repository on
+			// demand by SnapInspect, not here.
+			continue
+		}
+
+		v, err := self.get(volumeID)
+		if err != nil {
+			fmt.Printf("nfs: refresh: skipping %s: %v\n", volumeID, err)
+			continue
+		}
+
+		if _, err := os.Stat(v.Device); os.IsNotExist(err) {
+			fmt.Printf("nfs: refresh: dropping %s, backing directory %s is gone\n", volumeID, v.Device)
+			self.del(volumeID)
+			continue
+		}
+
+		if v.Mounted && mounted[v.Mountpath] {
+			// Kernel and kvdb agree; nothing to reconcile.
+			continue
+		}
+
+		if v.Mounted && !mounted[v.Mountpath] {
+			stalePath := v.Mountpath
+			v.Mounted = false
+			v.Mountpath = ""
+
+			// An operator opts a volume into auto-remount by dropping a
+			// ".autoremount" marker file in its directory; everything else
+			// comes back up unmounted and waits for an explicit Mount call.
+			if _, err := os.Stat(v.Device + "/.autoremount"); err == nil {
+				if err := syscall.Mount(v.Device, stalePath, string(v.Spec.Format), 0, ""); err != nil {
+					fmt.Printf("nfs: refresh: auto-remount of %s at %s failed: %v\n", volumeID, stalePath, err)
+				} else {
+					fmt.Printf("nfs: refresh: auto-remounted %s at %s\n", volumeID, stalePath)
+					v.Mounted = true
+					v.Mountpath = stalePath
+				}
+			} else {
+				fmt.Printf("nfs: refresh: clearing stale mount state for %s at %s\n", volumeID, stalePath)
+			}
+		}
+
+		if err := self.put(volumeID, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,312 @@
+package nfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+)
+
+const (
+	// kopiaDir is the name of the Kopia repository directory created on the
+	// same NFS export that backs the volumes themselves.
+	kopiaDir = ".kopia"
+
+	// snapKeyPrefix namespaces snapshot records away from volume records in
+	// kvdb while keeping them under the driver's existing NfsDBKey tree.
+	snapKeyPrefix = NfsDBKey + "/snap/"
+)
+
+// snapSource abstracts the thing a snapshot is taken of. The NFS driver
+// snapshots a directory (v.Device), but a future block driver only has a
+// single backing device/content stream to hand the snapshot manager --
+// implementing snapSource is all that is required to reuse snapshotMgr.
+type snapSource interface {
+	// Path returns the on-disk path (directory or raw device) to snapshot.
+	Path() string
+
+	// Quiesce is called immediately before the Kopia snapshot is taken so
+	// the source can flush/freeze itself. Best-effort: errors are logged,
+	// not fatal, since most filesystems don't support freezing.
+	Quiesce() error
+}
+
+// dirSource snapshots a plain directory tree, which is what nfsProvider
+// volumes are today.
+type dirSource struct {
+	path string
+}
+
+func (d *dirSource) Path() string { return d.path }
+
+func (d *dirSource) Quiesce() error {
+	// Best-effort: flush dirty pages for this export before snapshotting.
+	// FIFREEZE is not attempted here since NFS-backed mounts don't support
+	// it; local re-exported filesystems that do can be frozen by a future
+	// source implementation without changing snapshotMgr.
+	syscall.Sync()
+	return nil
+}
+
+// snapRecord is the awsVolume-style record persisted per snapshot.
+type snapRecord struct {
+	ID        api.SnapID
+	VolumeID  api.VolumeID
+	Labels    api.Labels
+	CreatedAt time.Time
+	// ManifestID is the Kopia snapshot manifest ID; it doubles as the
+	// snapshot ID exposed to callers.
+	ManifestID string
+	// Spec is the source volume's spec at snapshot time, carried forward so
+	// RestoreSnapshot can give the restored volume a usable Format/Labels
+	// instead of a zero api.VolumeSpec.
+	Spec api.VolumeSpec
+}
+
+// snapshotMgr wraps a Kopia repository rooted at <mntPath>/.kopia. It is
+// deliberately independent of nfsProvider's on-disk layout beyond the
+// snapSource it is handed, so a block-mode driver can plug in its own
+// reader/writer without touching this file.
+type snapshotMgr struct {
+	repoPath string
+}
+
+func newSnapshotMgr(mntPath string) (*snapshotMgr, error) {
+	repoPath := mntPath + "/" + kopiaDir
+
+	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(repoPath, 0700); err != nil {
+			return nil, err
+		}
+		if err := kopia("repository", "create", "filesystem", "--path", repoPath); err != nil {
+			return nil, err
+		}
+	} else if err := kopia("repository", "connect", "filesystem", "--path", repoPath); err != nil {
+		return nil, err
+	}
+
+	return &snapshotMgr{repoPath: repoPath}, nil
+}
+
+// snapshot takes a Kopia snapshot of src, tagged with volumeID and labels,
+// and returns the resulting manifest ID.
+func (s *snapshotMgr) snapshot(src snapSource, volumeID api.VolumeID, labels api.Labels) (string, error) {
+	if err := src.Quiesce(); err != nil {
+		fmt.Printf("nfs: best-effort quiesce failed for %v: %v\n", volumeID, err)
+	}
+
+	// --json is required here: without it kopia prints its human-readable
+	// progress/summary to stderr and leaves stdout empty, so there would be
+	// nothing to recover the manifest ID from.
+	args := []string{"snapshot", "create", src.Path(), "--json", "--tags", "volume:" + string(volumeID)}
+	for k, v := range labels {
+		args = append(args, "--tags", k+":"+v)
+	}
+
+	out, err := exec.Command("kopia", args...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return "", fmt.Errorf("nfs: parsing kopia snapshot create output for %v: %v", volumeID, err)
+	}
+	if result.ID == "" {
+		return "", fmt.Errorf("nfs: kopia returned an empty manifest ID for %v", volumeID)
+	}
+	return result.ID, nil
+}
+
+func (s *snapshotMgr) delete(manifestID string) error {
+	if err := kopia("snapshot", "delete", manifestID, "--unsafe-ignore-source"); err != nil {
+		return err
+	}
+	// Maintenance/GC pass so deleted manifests actually free space.
+	return kopia("maintenance", "run")
+}
+
+// restore streams the contents of manifestID into dst.
+func (s *snapshotMgr) restore(manifestID, dst string) error {
+	return kopia("snapshot", "restore", manifestID, dst)
+}
+
+func kopia(args ...string) error {
+	out, err := exec.Command("kopia", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nfs: kopia %v: %v: %s", args, err, out)
+	}
+	return nil
+}
+
+func (self *nfsProvider) snapKey(snapID api.SnapID) string {
+	return snapKeyPrefix + string(snapID)
+}
+
+func (self *nfsProvider) getSnap(snapID api.SnapID) (*snapRecord, error) {
+	r := &snapRecord{}
+	_, err := self.db.GetVal(self.snapKey(snapID), r)
+	return r, err
+}
+
+func (self *nfsProvider) putSnap(r *snapRecord) error {
+	_, err := self.db.Put(self.snapKey(r.ID), r, 0)
+	return err
+}
+
+// Snapshot quiesces the volume directory and hands it to the Kopia
+// repository rooted under <mntPath>/.kopia, persisting the resulting
+// manifest as a snapshot record under NfsDBKey + "/snap/".
+func (self *nfsProvider) Snapshot(volumeID api.VolumeID, labels api.Labels) (api.SnapID, error) {
+	v, err := self.get(string(volumeID))
+	if err != nil {
+		return "", err
+	}
+
+	mgr, err := newSnapshotMgr(self.mntPath)
+	if err != nil {
+		return "", err
+	}
+
+	manifestID, err := mgr.snapshot(&dirSource{path: v.Device}, volumeID, labels)
+	if err != nil {
+		return "", err
+	}
+
+	snapID := api.SnapID(manifestID)
+	record := &snapRecord{
+		ID:         snapID,
+		VolumeID:   volumeID,
+		Labels:     labels,
+		CreatedAt:  time.Now(),
+		ManifestID: manifestID,
+		Spec:       v.Spec,
+	}
+	if err := self.putSnap(record); err != nil {
+		return "", err
+	}
+
+	return snapID, nil
+}
+
+// SnapDelete deletes the Kopia manifest backing snapID and triggers a
+// maintenance/GC pass, then drops the kvdb record.
+func (self *nfsProvider) SnapDelete(snapID api.SnapID) error {
+	record, err := self.getSnap(snapID)
+	if err != nil {
+		return err
+	}
+
+	mgr, err := newSnapshotMgr(self.mntPath)
+	if err != nil {
+		return err
+	}
+
+	if err := mgr.delete(record.ManifestID); err != nil {
+		return err
+	}
+
+	self.db.Delete(self.snapKey(snapID))
+	return nil
+}
+
+// SnapInspect lists the requested snapshot records from kvdb, cross-checking
+// each against the Kopia repository.
+func (self *nfsProvider) SnapInspect(snapIDs []api.SnapID) ([]api.VolumeSnap, error) {
+	mgr, err := newSnapshotMgr(self.mntPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := make([]api.VolumeSnap, 0, len(snapIDs))
+	for _, id := range snapIDs {
+		record, err := self.getSnap(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := kopia("manifest", "show", record.ManifestID); err != nil {
+			return nil, fmt.Errorf("nfs: snapshot %v missing from repository %s: %v", id, mgr.repoPath, err)
+		}
+		snaps = append(snaps, api.VolumeSnap{
+			ID:       record.ID,
+			VolumeID: record.VolumeID,
+			Labels:   record.Labels,
+		})
+	}
+	return snaps, nil
+}
+
+// SnapEnumerate scans kvdb for every snapshot record under NfsDBKey + "/snap/"
+// and filters client-side by matching labels against each snapshot's own
+// labels. locator is not used: snapshots are not filtered by name or by
+// locator labels.
+func (self *nfsProvider) SnapEnumerate(locator api.VolumeLocator, labels api.Labels) ([]api.VolumeSnap, error) {
+	kvps, err := self.db.Enumerate(snapKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := make([]api.VolumeSnap, 0, len(kvps))
+	for _, kvp := range kvps {
+		record := &snapRecord{}
+		if err := json.Unmarshal(kvp.Value, record); err != nil {
+			return nil, err
+		}
+		if !matchesLabels(record.Labels, labels) {
+			continue
+		}
+		snaps = append(snaps, api.VolumeSnap{
+			ID:       record.ID,
+			VolumeID: record.VolumeID,
+			Labels:   record.Labels,
+		})
+	}
+	return snaps, nil
+}
+
+// RestoreSnapshot creates a fresh volume directory and streams the snapshot
+// contents into it, registering the result as newVolumeID.
+func (self *nfsProvider) RestoreSnapshot(snapID api.SnapID, newVolumeID api.VolumeID) error {
+	record, err := self.getSnap(snapID)
+	if err != nil {
+		return err
+	}
+
+	device := self.devicePath(string(newVolumeID))
+	if err := os.Mkdir(device, 0744); err != nil {
+		return err
+	}
+
+	mgr, err := newSnapshotMgr(self.mntPath)
+	if err != nil {
+		os.RemoveAll(device)
+		return err
+	}
+
+	if err := mgr.restore(record.ManifestID, device); err != nil {
+		os.RemoveAll(device)
+		return err
+	}
+
+	if err := self.put(string(newVolumeID), &awsVolume{Device: device, Spec: record.Spec}); err != nil {
+		os.RemoveAll(device)
+		return err
+	}
+
+	return nil
+}
+
+func matchesLabels(have, want api.Labels) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package nfs
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/libopenstorage/openstorage/api"
+)
+
+// Stats reports capacity from statfs(2) on the volume's backing directory
+// plus NFS op-count/latency counters scraped from /proc/self/mountstats.
+func (self *nfsProvider) Stats(volumeID api.VolumeID) (api.VolumeStats, error) {
+	v, err := self.get(string(volumeID))
+	if err != nil {
+		return api.VolumeStats{}, err
+	}
+
+	var statfs syscall.Statfs_t
+	if err := syscall.Statfs(v.Device, &statfs); err != nil {
+		return api.VolumeStats{}, err
+	}
+
+	blockSize := uint64(statfs.Bsize)
+	total := statfs.Blocks * blockSize
+	free := statfs.Bfree * blockSize
+	used := total - free
+
+	stats := api.VolumeStats{
+		Total: total,
+		Used:  used,
+		Free:  free,
+	}
+
+	if nfsStats, err := readNFSMountStats(self.mntPath); err == nil {
+		stats.NFSOpCounts = nfsStats.opCounts
+		stats.NFSRTTMillis = nfsStats.rttMillis
+		stats.NFSExecMillis = nfsStats.execMillis
+	}
+
+	return stats, nil
+}
+
+type nfsMountStats struct {
+	opCounts   map[string]uint64
+	rttMillis  map[string]uint64
+	execMillis map[string]uint64
+}
+
+// readNFSMountStats parses the "per-op statistics" table of the NFS section
+// of /proc/self/mountstats for the mount matching mntPath. Each line there
+// is: "<OP>: <ops> <trans> <timeouts> <bytes_sent> <bytes_recv> <queue_ms>
+// <rtt_ms> <exec_ms> ..."
+func readNFSMountStats(mntPath string) (*nfsMountStats, error) {
+	f, err := os.Open("/proc/self/mountstats")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := &nfsMountStats{
+		opCounts:   map[string]uint64{},
+		rttMillis:  map[string]uint64{},
+		execMillis: map[string]uint64{},
+	}
+
+	scanner := bufio.NewScanner(f)
+	inTargetMount := false
+	inPerOpStats := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "device ") {
+			inTargetMount = strings.Contains(line, " mounted on "+mntPath+" ")
+			inPerOpStats = false
+			continue
+		}
+		if !inTargetMount {
+			continue
+		}
+		if trimmed == "per-op statistics" {
+			inPerOpStats = true
+			continue
+		}
+		if !inPerOpStats {
+			continue
+		}
+		if trimmed == "" {
+			inPerOpStats = false
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		if len(fields) < 9 {
+			continue
+		}
+		op := strings.TrimSuffix(fields[0], ":")
+		ops, _ := strconv.ParseUint(fields[1], 10, 64)
+		rtt, _ := strconv.ParseUint(fields[7], 10, 64)
+		exec, _ := strconv.ParseUint(fields[8], 10, 64)
+
+		stats.opCounts[op] = ops
+		stats.rttMillis[op] = rtt
+		stats.execMillis[op] = exec
+	}
+
+	return stats, scanner.Err()
+}
@@ -0,0 +1,204 @@
+package osdconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/portworx/kvdb"
+)
+
+const (
+	// historyKey roots every revision record: baseKey + "/history/<kind>/<rev>".
+	historyKey = "history"
+
+	// revCounterKey roots each kind's own revision counter, kept out of the
+	// history/<kind> subtree so ListRevisions' enumeration never sees it.
+	revCounterKey = "rev"
+
+	// maxRevisions is the number of revisions kept per kind before the
+	// oldest are pruned. It is a fixed constant; there is no setting that
+	// changes it.
+	maxRevisions = 50
+)
+
+// Revision is one entry in a kind's config history.
+type Revision struct {
+	Rev    uint64 `json:"rev"`
+	Kind   string `json:"kind"`
+	Config string `json:"config"`
+	Author string `json:"author"`
+	Reason string `json:"reason"`
+}
+
+func revisionKey(kind string, rev uint64) string {
+	return filepath.Join(baseKey, historyKey, kind, strconv.FormatUint(rev, 10))
+}
+
+func revCounterKeyFor(kind string) string {
+	return filepath.Join(baseKey, revCounterKey, kind)
+}
+
+// nextRevision atomically increments kind's own counter via
+// compare-and-swap, retrying on a lost race. Each kind gets its own counter
+// (rather than one shared across all kinds) so that kind's revisions in
+// history/<kind>/ are numbered contiguously from 1 -- which is what lets
+// pruneRevisions below trim by simple subtraction.
+func (c *configManager) nextRevision(kind string) (uint64, error) {
+	key := revCounterKeyFor(kind)
+
+	for {
+		kvp, err := c.kv.Get(key)
+		if err != nil && err != kvdb.ErrNotFound {
+			return 0, err
+		}
+
+		var cur uint64
+		var prevKvp *kvdb.KVPair
+		if err == nil {
+			prevKvp = kvp
+			cur, err = strconv.ParseUint(string(kvp.Value), 10, 64)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		next := cur + 1
+		val := []byte(strconv.FormatUint(next, 10))
+
+		if prevKvp == nil {
+			if _, err := c.kv.Create(key, val, 0); err != nil {
+				if err == kvdb.ErrExist {
+					continue
+				}
+				return 0, err
+			}
+			return next, nil
+		}
+
+		newKvp := &kvdb.KVPair{Key: key, Value: val}
+		if _, err := c.kv.CompareAndSet(newKvp, kvdb.KVFlags(0), prevKvp.Value); err != nil {
+			if err == kvdb.ErrValueMismatch {
+				continue
+			}
+			return 0, err
+		}
+		return next, nil
+	}
+}
+
+// recordRevision stores config as a new revision of kind.
+func (c *configManager) recordRevision(kind string, config []byte, author, reason string) (uint64, error) {
+	rev, err := c.nextRevision(kind)
+	if err != nil {
+		return 0, err
+	}
+
+	r := Revision{
+		Rev:    rev,
+		Kind:   kind,
+		Config: string(config),
+		Author: author,
+		Reason: reason,
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := c.kv.Put(revisionKey(kind, rev), data, 0); err != nil {
+		return 0, err
+	}
+
+	c.pruneRevisions(kind, rev)
+	return rev, nil
+}
+
+// pruneRevisions deletes the revision that falls maxRevisions behind latest.
+// Because nextRevision hands out a contiguous 1..N sequence per kind, this
+// is always the oldest surviving revision, not some other kind's entry.
+func (c *configManager) pruneRevisions(kind string, latest uint64) {
+	if latest <= maxRevisions {
+		return
+	}
+	oldest := latest - maxRevisions
+	c.kv.Delete(revisionKey(kind, oldest))
+}
+
+// snapshotBeforeWrite records kind's current value as a new revision right
+// before it is overwritten. It is called by every write path -- the
+// cluster/node config setters in manager.go as well as Rollback below -- so
+// ListRevisions always has the config as it stood before each change, not
+// just before a rollback.
+func (c *configManager) snapshotBeforeWrite(kind, author, reason string) error {
+	current, err := c.kv.Get(filepath.Join(baseKey, kind))
+	if err != nil {
+		if err == kvdb.ErrNotFound {
+			// Nothing live yet for this kind -- first write, nothing to
+			// snapshot.
+			return nil
+		}
+		return err
+	}
+
+	_, err = c.recordRevision(kind, current.Value, author, reason)
+	return err
+}
+
+// ListRevisions returns every retained revision for kind, oldest first.
+func (c *configManager) ListRevisions(kind string) ([]Revision, error) {
+	kvps, err := c.kv.Enumerate(filepath.Join(baseKey, historyKey, kind))
+	if err != nil {
+		if err == kvdb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	revs := make([]Revision, 0, len(kvps))
+	for _, kvp := range kvps {
+		r := Revision{}
+		if err := json.Unmarshal(kvp.Value, &r); err != nil {
+			return nil, err
+		}
+		revs = append(revs, r)
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Rev < revs[j].Rev })
+	return revs, nil
+}
+
+// GetRevision fetches a single revision of kind's config.
+func (c *configManager) GetRevision(kind string, rev uint64) (Revision, error) {
+	kvp, err := c.kv.Get(revisionKey(kind, rev))
+	if err != nil {
+		return Revision{}, err
+	}
+	r := Revision{}
+	if err := json.Unmarshal(kvp.Value, &r); err != nil {
+		return Revision{}, err
+	}
+	return r, nil
+}
+
+// Rollback re-writes kind's current config to the value recorded at rev,
+// through the same kvdb path newManager's WatchTree callbacks are watching,
+// so CallbackClusterConfigFunc/CallbackNodeConfigFunc handlers fire exactly
+// as they would for any other config change. The config that was live
+// before the rollback is itself snapshotted first, via the same
+// snapshotBeforeWrite path every other write goes through, so a rollback is
+// one more entry in the history rather than a silent edit.
+func (c *configManager) Rollback(kind string, rev uint64, author, reason string) error {
+	target, err := c.GetRevision(kind, rev)
+	if err != nil {
+		return fmt.Errorf("osdconfig: rollback %s to rev %d: %v", kind, rev, err)
+	}
+
+	if err := c.snapshotBeforeWrite(kind, author, reason); err != nil {
+		return err
+	}
+
+	_, err = c.kv.Put(filepath.Join(baseKey, kind), []byte(target.Config), 0)
+	return err
+}
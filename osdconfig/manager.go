@@ -0,0 +1,183 @@
+package osdconfig
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/portworx/kvdb"
+)
+
+const (
+	// baseKey roots every key this package writes to kvdb.
+	baseKey = "openstorage/config"
+
+	clusterKey = "cluster"
+	nodeKey    = "node"
+)
+
+// watcherType tells kvdbCallback which kind of config changed.
+type watcherType int
+
+const (
+	clusterWatcher watcherType = iota
+	nodeWatcher
+)
+
+// dataToKvdb is the opaque value threaded through kv.WatchTree back to
+// kvdbCallback, identifying which watch fired.
+type dataToKvdb struct {
+	Type watcherType
+}
+
+// ClusterConfig is the cluster-wide configuration document.
+type ClusterConfig struct {
+	ClusterId string            `json:"cluster_id"`
+	Config    map[string]string `json:"config"`
+}
+
+// NodeConfig is a single node's configuration document.
+type NodeConfig struct {
+	NodeId string            `json:"node_id"`
+	Config map[string]string `json:"config"`
+}
+
+// CallbackClusterConfigFunc is invoked whenever the cluster config changes,
+// whether from a local Set or a remote kvdb write.
+type CallbackClusterConfigFunc func(*ClusterConfig)
+
+// CallbackNodeConfigFunc is invoked whenever a node config changes.
+type CallbackNodeConfigFunc func(*NodeConfig)
+
+// ConfigManager is the public interface to the cluster/node config store:
+// reading and writing the live config, watching for changes, and -- via the
+// history subsystem in history.go -- inspecting and rolling back to a prior
+// revision.
+type ConfigManager interface {
+	SetClusterConfig(config *ClusterConfig, author, reason string) error
+	GetClusterConfig() (*ClusterConfig, error)
+
+	SetNodeConfig(config *NodeConfig, author, reason string) error
+	GetNodeConfig(nodeID string) (*NodeConfig, error)
+
+	WatchCluster(id string, cb CallbackClusterConfigFunc) error
+	WatchNode(id string, cb CallbackNodeConfigFunc) error
+
+	ListRevisions(kind string) ([]Revision, error)
+	GetRevision(kind string, rev uint64) (Revision, error)
+	Rollback(kind string, rev uint64, author, reason string) error
+}
+
+type configManager struct {
+	kv        kvdb.Kvdb
+	cbCluster map[string]CallbackClusterConfigFunc
+	cbNode    map[string]CallbackNodeConfigFunc
+}
+
+// kvdbCallback is registered with kv.WatchTree in newManager for both the
+// cluster and node subtrees; opaque tells it which one fired.
+func (c *configManager) kvdbCallback(prefix string, opaque interface{}, kvp *kvdb.KVPair, err error) error {
+	if err != nil {
+		return err
+	}
+
+	d, ok := opaque.(*dataToKvdb)
+	if !ok {
+		return nil
+	}
+
+	switch d.Type {
+	case clusterWatcher:
+		cfg := &ClusterConfig{}
+		if err := json.Unmarshal(kvp.Value, cfg); err != nil {
+			return err
+		}
+		for _, cb := range c.cbCluster {
+			cb(cfg)
+		}
+	case nodeWatcher:
+		cfg := &NodeConfig{}
+		if err := json.Unmarshal(kvp.Value, cfg); err != nil {
+			return err
+		}
+		for _, cb := range c.cbNode {
+			cb(cfg)
+		}
+	}
+
+	return nil
+}
+
+func nodeHistoryKind(nodeID string) string {
+	return filepath.Join(nodeKey, nodeID)
+}
+
+// SetClusterConfig snapshots the previous cluster config into the history
+// subsystem, then writes the new one through the same kvdb path newManager
+// watches, so registered CallbackClusterConfigFunc handlers fire via
+// kvdbCallback exactly as they would for an external writer.
+func (c *configManager) SetClusterConfig(config *ClusterConfig, author, reason string) error {
+	return c.writeConfig(clusterKey, config, author, reason)
+}
+
+// GetClusterConfig returns the live cluster config.
+func (c *configManager) GetClusterConfig() (*ClusterConfig, error) {
+	kvp, err := c.kv.Get(filepath.Join(baseKey, clusterKey))
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ClusterConfig{}
+	if err := json.Unmarshal(kvp.Value, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// SetNodeConfig snapshots the previous config for this node into the
+// history subsystem, then writes the new one the same way SetClusterConfig
+// does for the cluster config.
+func (c *configManager) SetNodeConfig(config *NodeConfig, author, reason string) error {
+	return c.writeConfig(nodeHistoryKind(config.NodeId), config, author, reason)
+}
+
+// GetNodeConfig returns the live config for nodeID.
+func (c *configManager) GetNodeConfig(nodeID string) (*NodeConfig, error) {
+	kvp, err := c.kv.Get(filepath.Join(baseKey, nodeHistoryKind(nodeID)))
+	if err != nil {
+		return nil, err
+	}
+	cfg := &NodeConfig{}
+	if err := json.Unmarshal(kvp.Value, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WatchCluster registers cb to be called on every cluster config change.
+func (c *configManager) WatchCluster(id string, cb CallbackClusterConfigFunc) error {
+	c.cbCluster[id] = cb
+	return nil
+}
+
+// WatchNode registers cb to be called on every node config change.
+func (c *configManager) WatchNode(id string, cb CallbackNodeConfigFunc) error {
+	c.cbNode[id] = cb
+	return nil
+}
+
+// writeConfig writes baseKey + "/" + kind for SetClusterConfig and
+// SetNodeConfig, recording history first so it is kept on every write, not
+// just on Rollback (which writes the key itself, after the same
+// snapshotBeforeWrite call).
+func (c *configManager) writeConfig(kind string, config interface{}, author, reason string) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	if err := c.snapshotBeforeWrite(kind, author, reason); err != nil {
+		return err
+	}
+
+	_, err = c.kv.Put(filepath.Join(baseKey, kind), data, 0)
+	return err
+}
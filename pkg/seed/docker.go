@@ -0,0 +1,59 @@
+package seed
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// dockerSeeder pulls an image and extracts its rootfs into dst, for
+// "docker://<image>" seed URIs.
+type dockerSeeder struct{}
+
+func (dockerSeeder) Seed(dst, uri string, labels, env map[string]string) error {
+	image := strings.TrimPrefix(uri, "docker://")
+	if image == "" {
+		return fmt.Errorf("seed: docker seed URI %q has no image", uri)
+	}
+
+	if err := run("docker", "pull", image); err != nil {
+		return err
+	}
+
+	out, err := exec.Command("docker", "create", image).Output()
+	if err != nil {
+		return fmt.Errorf("seed: docker create %s: %v", image, err)
+	}
+	container := strings.TrimSpace(string(out))
+	defer exec.Command("docker", "rm", container).Run()
+
+	// `docker export | tar -x` is the standard way to materialize a
+	// container's rootfs onto disk without a running daemon mount.
+	export := exec.Command("docker", "export", container)
+	extract := exec.Command("tar", "-x", "-C", dst)
+
+	pipe, err := export.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	extract.Stdin = pipe
+
+	if err := extract.Start(); err != nil {
+		return err
+	}
+	if err := export.Start(); err != nil {
+		return fmt.Errorf("seed: docker export %s: %v", container, err)
+	}
+	if err := extract.Wait(); err != nil {
+		return fmt.Errorf("seed: extracting %s rootfs: %v", image, err)
+	}
+	if err := export.Wait(); err != nil {
+		return fmt.Errorf("seed: docker export %s: %v", container, err)
+	}
+
+	return nil
+}
+
+func init() {
+	Register("docker", dockerSeeder{})
+}
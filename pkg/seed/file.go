@@ -0,0 +1,38 @@
+package seed
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// fileSeeder copies a local template directory into dst, for "file://" seed
+// URIs.
+type fileSeeder struct{}
+
+func (fileSeeder) Seed(dst, uri string, labels, env map[string]string) error {
+	src := strings.TrimPrefix(uri, "file://")
+	if src == "" {
+		return fmt.Errorf("seed: file seed URI %q has no path", uri)
+	}
+
+	// rsync preserves ownership/permissions and, unlike "cp -a", already
+	// copies directory contents rather than the directory itself when the
+	// source is suffixed with "/".
+	if err := run("rsync", "-a", strings.TrimSuffix(src, "/")+"/", dst+"/"); err != nil {
+		return fmt.Errorf("seed: copying template %s: %v", src, err)
+	}
+	return nil
+}
+
+func init() {
+	Register("file", fileSeeder{})
+}
+
+func run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %v: %v: %s", name, args, err, out)
+	}
+	return nil
+}
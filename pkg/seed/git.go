@@ -0,0 +1,32 @@
+package seed
+
+import (
+	"fmt"
+)
+
+// gitSeeder shallow-clones a repository into dst, for "git://" and
+// "https://...git" seed URIs.
+type gitSeeder struct{}
+
+func (gitSeeder) Seed(dst, uri string, labels, env map[string]string) error {
+	// uri is passed straight through as the clone URL: git understands the
+	// git:// transport scheme natively, and https://...git is already a
+	// valid clone URL as-is, so there's nothing to strip for either.
+	repo := uri
+
+	ref := env["GIT_REF"]
+	args := []string{"clone", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, repo, dst)
+
+	if err := run("git", args...); err != nil {
+		return fmt.Errorf("seed: git clone %s: %v", repo, err)
+	}
+	return nil
+}
+
+func init() {
+	Register("git", gitSeeder{})
+}
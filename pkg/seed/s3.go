@@ -0,0 +1,25 @@
+package seed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// s3Seeder syncs an S3 bucket (or prefix) into dst, for "s3://" seed URIs.
+type s3Seeder struct{}
+
+func (s3Seeder) Seed(dst, uri string, labels, env map[string]string) error {
+	bucket := strings.TrimPrefix(uri, "s3://")
+	if bucket == "" {
+		return fmt.Errorf("seed: s3 seed URI %q has no bucket", uri)
+	}
+
+	if err := run("aws", "s3", "sync", "s3://"+bucket, dst); err != nil {
+		return fmt.Errorf("seed: syncing %s: %v", uri, err)
+	}
+	return nil
+}
+
+func init() {
+	Register("s3", s3Seeder{})
+}
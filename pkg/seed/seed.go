@@ -0,0 +1,62 @@
+// Package seed populates a freshly created volume directory from a seed URI
+// (api.CreateOptions.Seed), e.g. "docker://nginx:latest" or
+// "git://github.com/foo/bar". Each scheme is handled by a Seeder, and new
+// schemes can be added without touching callers by registering one in
+// init().
+package seed
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Seeder populates dst (an existing, empty directory) with the contents
+// referred to by uri. labels and env are threaded through from the volume's
+// locator/spec so a seeder can template its source (e.g. substitute env
+// into a docker tag or git ref).
+type Seeder interface {
+	// Seed populates dst from uri. Implementations should treat dst as
+	// already created and leave it untouched on error so the caller can
+	// decide how to roll back.
+	Seed(dst string, uri string, labels map[string]string, env map[string]string) error
+}
+
+var registry = map[string]Seeder{}
+
+// Register adds a Seeder for the given URI scheme (e.g. "docker"). It is
+// meant to be called from each seeder implementation's init().
+func Register(scheme string, s Seeder) {
+	registry[scheme] = s
+}
+
+// Resolve looks up the Seeder registered for uri's scheme.
+func Resolve(uri string) (Seeder, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("seed: invalid seed URI %q: %v", uri, err)
+	}
+
+	scheme := u.Scheme
+	if (scheme == "https" || scheme == "http") && strings.HasSuffix(u.Path, ".git") {
+		scheme = "git"
+	}
+
+	s, ok := registry[scheme]
+	if !ok {
+		return nil, fmt.Errorf("seed: no seeder registered for scheme %q (uri %q)", scheme, uri)
+	}
+	return s, nil
+}
+
+// Populate resolves uri to a Seeder and seeds dst with it. On error dst is
+// left as-is; callers are expected to remove it themselves, the same way
+// nfsProvider.Create rolls back its directory and kvdb entry on any other
+// Create failure.
+func Populate(dst, uri string, labels map[string]string, env map[string]string) error {
+	s, err := Resolve(uri)
+	if err != nil {
+		return err
+	}
+	return s.Seed(dst, uri, labels, env)
+}
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/libopenstorage/openstorage/volume"
+)
+
+const (
+	// SocketDir is the well-known directory external drivers drop their
+	// Unix-domain sockets into. The socket's base name (minus ".sock") is
+	// used as the driver name; a .spec in SpecDir with that same name
+	// replaces the socket path registered for it.
+	SocketDir = "/run/openstorage/plugins"
+
+	// SpecDir holds optional *.spec files (JSON: {"name": "...", "socket":
+	// "/path/to.sock"}) for plugins whose socket lives outside SocketDir.
+	SpecDir = "/etc/openstorage/plugins"
+
+	discoveryInterval = 5 * time.Second
+)
+
+// spec is the contents of an *.spec file.
+type spec struct {
+	Name   string `json:"name"`
+	Socket string `json:"socket"`
+}
+
+// Discover scans SocketDir and SpecDir once and registers every plugin found
+// under its reported name via volume.Register, skipping names that are
+// already registered (e.g. by an earlier scan, or a built-in driver).
+func Discover() error {
+	found, err := discoverOnce()
+	if err != nil {
+		return err
+	}
+	for name, sockPath := range found {
+		registerPlugin(name, sockPath)
+	}
+	return nil
+}
+
+// Watch runs Discover on a timer until stop is closed, so plugins dropped in
+// after openstorage has started are picked up without a restart.
+func Watch(stop <-chan struct{}) {
+	ticker := time.NewTicker(discoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := Discover(); err != nil {
+				fmt.Printf("plugin: discovery scan failed: %v\n", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func discoverOnce() (map[string]string, error) {
+	found := make(map[string]string)
+
+	socks, err := filepath.Glob(filepath.Join(SocketDir, "*.sock"))
+	if err != nil {
+		return nil, err
+	}
+	for _, sock := range socks {
+		name := strings.TrimSuffix(filepath.Base(sock), ".sock")
+		found[name] = sock
+	}
+
+	specs, err := filepath.Glob(filepath.Join(SpecDir, "*.spec"))
+	if err != nil {
+		return nil, err
+	}
+	for _, specPath := range specs {
+		data, err := ioutil.ReadFile(specPath)
+		if err != nil {
+			fmt.Printf("plugin: skipping unreadable spec %s: %v\n", specPath, err)
+			continue
+		}
+		s := spec{}
+		if err := json.Unmarshal(data, &s); err != nil {
+			fmt.Printf("plugin: skipping malformed spec %s: %v\n", specPath, err)
+			continue
+		}
+		if s.Name == "" || s.Socket == "" {
+			continue
+		}
+		found[s.Name] = s.Socket
+	}
+
+	return found, nil
+}
+
+func registerPlugin(name, sockPath string) {
+	err := volume.Register(name, volume.TypePlugin, func(params volume.DriverParams) (volume.VolumeDriver, error) {
+		return NewPluginDriver(name, sockPath), nil
+	})
+	if err != nil {
+		// Already registered (built-in driver of the same name, or a prior
+		// scan) -- not fatal, just skip it.
+		fmt.Printf("plugin: not registering %q from %s: %v\n", name, sockPath, err)
+	}
+}
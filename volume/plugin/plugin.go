@@ -0,0 +1,292 @@
+// Package plugin lets external processes implement volume.VolumeDriver over
+// a Unix-domain socket instead of being compiled into the openstorage
+// binary. A plugin is just an HTTP server speaking the JSON contract defined
+// in this package; see the plugin/server subpackage for a ~100 line reference
+// implementation.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/openstorage/volume"
+)
+
+// Method names in the JSON-over-HTTP contract. Each is POSTed to
+// "/VolumeDriver.<Method>" with a JSON-encoded request body and a
+// JSON-encoded response, mirroring volume.VolumeDriver.
+const (
+	MethodCreate          = "Create"
+	MethodDelete          = "Delete"
+	MethodMount           = "Mount"
+	MethodUnmount         = "Unmount"
+	MethodInspect         = "Inspect"
+	MethodEnumerate       = "Enumerate"
+	MethodSnapshot        = "Snapshot"
+	MethodSnapDelete      = "SnapDelete"
+	MethodSnapInspect     = "SnapInspect"
+	MethodSnapEnumerate   = "SnapEnumerate"
+	MethodStats           = "Stats"
+	MethodAlerts          = "Alerts"
+	MethodShutdown        = "Shutdown"
+)
+
+// request/response envelopes. Every call shares the same shape: a request
+// struct is marshaled to the body, and the response struct's Error field is
+// non-empty on failure.
+type createRequest struct {
+	Locator api.VolumeLocator  `json:"locator"`
+	Options *api.CreateOptions `json:"options"`
+	Spec    *api.VolumeSpec    `json:"spec"`
+}
+
+type createResponse struct {
+	VolumeID api.VolumeID `json:"volume_id"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type volumeIDRequest struct {
+	VolumeID api.VolumeID `json:"volume_id"`
+}
+
+type mountRequest struct {
+	VolumeID  api.VolumeID `json:"volume_id"`
+	MountPath string       `json:"mount_path"`
+}
+
+type errorResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+type inspectRequest struct {
+	VolumeIDs []api.VolumeID `json:"volume_ids"`
+}
+
+type inspectResponse struct {
+	Volumes []api.Volume `json:"volumes"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type enumerateRequest struct {
+	Locator api.VolumeLocator `json:"locator"`
+	Labels  api.Labels        `json:"labels"`
+}
+
+type enumerateResponse struct {
+	Volumes []api.Volume `json:"volumes"`
+	Error   string       `json:"error,omitempty"`
+}
+
+type snapshotRequest struct {
+	VolumeID api.VolumeID `json:"volume_id"`
+	Labels   api.Labels   `json:"labels"`
+}
+
+type snapshotResponse struct {
+	SnapID api.SnapID `json:"snap_id"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type snapIDRequest struct {
+	SnapID api.SnapID `json:"snap_id"`
+}
+
+type snapInspectRequest struct {
+	SnapIDs []api.SnapID `json:"snap_ids"`
+}
+
+type snapInspectResponse struct {
+	Snaps []api.VolumeSnap `json:"snaps"`
+	Error string           `json:"error,omitempty"`
+}
+
+type snapEnumerateResponse struct {
+	Snaps []api.VolumeSnap `json:"snaps"`
+	Error string           `json:"error,omitempty"`
+}
+
+type statsResponse struct {
+	Stats api.VolumeStats `json:"stats"`
+	Error string          `json:"error,omitempty"`
+}
+
+type alertsResponse struct {
+	Alerts api.VolumeAlerts `json:"alerts"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// PluginDriver implements volume.VolumeDriver by dispatching calls over an
+// HTTP client bound to a Unix-domain socket. Attach, Detach, RestoreSnapshot
+// and Refresh are not dispatched: they come from the embedded
+// DefaultBlockDriver and return volume.ErrNotSupported.
+type PluginDriver struct {
+	volume.DefaultBlockDriver
+	name   string
+	client *http.Client
+}
+
+// NewPluginDriver returns a VolumeDriver that forwards calls to the plugin
+// listening on sockPath, identifying itself as name for logging purposes.
+func NewPluginDriver(name, sockPath string) *PluginDriver {
+	return &PluginDriver{
+		name: name,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					d := net.Dialer{}
+					return d.DialContext(ctx, "unix", sockPath)
+				},
+			},
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (p *PluginDriver) String() string {
+	return p.name
+}
+
+func (p *PluginDriver) call(method string, req, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	// The host:port in this URL is never resolved; DialContext above always
+	// connects to the plugin's Unix socket instead.
+	httpResp, err := p.client.Post("http://plugin/VolumeDriver."+method, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("plugin %s: %v", p.name, err)
+	}
+	defer httpResp.Body.Close()
+
+	out, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(out, resp); err != nil {
+		return fmt.Errorf("plugin %s: malformed response to %s: %v", p.name, method, err)
+	}
+	return nil
+}
+
+func (p *PluginDriver) Create(locator api.VolumeLocator, opt *api.CreateOptions, spec *api.VolumeSpec) (api.VolumeID, error) {
+	resp := &createResponse{}
+	if err := p.call(MethodCreate, &createRequest{Locator: locator, Options: opt, Spec: spec}, resp); err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%s", resp.Error)
+	}
+	return resp.VolumeID, nil
+}
+
+func (p *PluginDriver) Delete(volumeID api.VolumeID) error {
+	resp := &errorResponse{}
+	if err := p.call(MethodDelete, &volumeIDRequest{VolumeID: volumeID}, resp); err != nil {
+		return err
+	}
+	return asError(resp.Error)
+}
+
+func (p *PluginDriver) Mount(volumeID api.VolumeID, mountpath string) error {
+	resp := &errorResponse{}
+	if err := p.call(MethodMount, &mountRequest{VolumeID: volumeID, MountPath: mountpath}, resp); err != nil {
+		return err
+	}
+	return asError(resp.Error)
+}
+
+func (p *PluginDriver) Unmount(volumeID api.VolumeID, mountpath string) error {
+	resp := &errorResponse{}
+	if err := p.call(MethodUnmount, &mountRequest{VolumeID: volumeID, MountPath: mountpath}, resp); err != nil {
+		return err
+	}
+	return asError(resp.Error)
+}
+
+func (p *PluginDriver) Inspect(volumeIDs []api.VolumeID) ([]api.Volume, error) {
+	resp := &inspectResponse{}
+	if err := p.call(MethodInspect, &inspectRequest{VolumeIDs: volumeIDs}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Volumes, asError(resp.Error)
+}
+
+func (p *PluginDriver) Enumerate(locator api.VolumeLocator, labels api.Labels) ([]api.Volume, error) {
+	resp := &enumerateResponse{}
+	if err := p.call(MethodEnumerate, &enumerateRequest{Locator: locator, Labels: labels}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Volumes, asError(resp.Error)
+}
+
+func (p *PluginDriver) Snapshot(volumeID api.VolumeID, labels api.Labels) (api.SnapID, error) {
+	resp := &snapshotResponse{}
+	if err := p.call(MethodSnapshot, &snapshotRequest{VolumeID: volumeID, Labels: labels}, resp); err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%s", resp.Error)
+	}
+	return resp.SnapID, nil
+}
+
+func (p *PluginDriver) SnapDelete(snapID api.SnapID) error {
+	resp := &errorResponse{}
+	if err := p.call(MethodSnapDelete, &snapIDRequest{SnapID: snapID}, resp); err != nil {
+		return err
+	}
+	return asError(resp.Error)
+}
+
+func (p *PluginDriver) SnapInspect(snapIDs []api.SnapID) ([]api.VolumeSnap, error) {
+	resp := &snapInspectResponse{}
+	if err := p.call(MethodSnapInspect, &snapInspectRequest{SnapIDs: snapIDs}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Snaps, asError(resp.Error)
+}
+
+func (p *PluginDriver) SnapEnumerate(locator api.VolumeLocator, labels api.Labels) ([]api.VolumeSnap, error) {
+	resp := &snapEnumerateResponse{}
+	if err := p.call(MethodSnapEnumerate, &enumerateRequest{Locator: locator, Labels: labels}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Snaps, asError(resp.Error)
+}
+
+func (p *PluginDriver) Stats(volumeID api.VolumeID) (api.VolumeStats, error) {
+	resp := &statsResponse{}
+	if err := p.call(MethodStats, &volumeIDRequest{VolumeID: volumeID}, resp); err != nil {
+		return api.VolumeStats{}, err
+	}
+	return resp.Stats, asError(resp.Error)
+}
+
+func (p *PluginDriver) Alerts(volumeID api.VolumeID) (api.VolumeAlerts, error) {
+	resp := &alertsResponse{}
+	if err := p.call(MethodAlerts, &volumeIDRequest{VolumeID: volumeID}, resp); err != nil {
+		return api.VolumeAlerts{}, err
+	}
+	return resp.Alerts, asError(resp.Error)
+}
+
+func (p *PluginDriver) Shutdown() {
+	resp := &errorResponse{}
+	p.call(MethodShutdown, struct{}{}, resp)
+}
+
+func asError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,108 @@
+// Package server is a reference implementation of the plugin side of the
+// contract in volume/plugin: it takes any volume.VolumeDriver implementation
+// and serves it over a Unix-domain socket, so a third party can stand up an
+// out-of-process driver in a handful of lines:
+//
+//	drv := myDriver{}
+//	ln, _ := server.Listen("/run/openstorage/plugins/mydriver.sock")
+//	log.Fatal(server.Serve(ln, drv))
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/libopenstorage/openstorage/api"
+	"github.com/libopenstorage/openstorage/volume"
+	"github.com/libopenstorage/openstorage/volume/plugin"
+)
+
+// Listen creates (replacing any stale socket file) and binds a Unix-domain
+// socket at sockPath, ready to be handed to Serve.
+func Listen(sockPath string) (net.Listener, error) {
+	if _, err := os.Stat(sockPath); err == nil {
+		os.Remove(sockPath)
+	}
+	return net.Listen("unix", sockPath)
+}
+
+// Serve answers the Mount, Unmount, Delete and Stats methods of the
+// plugin.Method* contract by dispatching to drv, JSON-encoding whatever it
+// returns. No other method is served: the mux answers those with 404.
+func Serve(ln net.Listener, drv volume.VolumeDriver) error {
+	mux := http.NewServeMux()
+
+	handle := func(method string, fn func(body []byte) interface{}) {
+		mux.HandleFunc("/VolumeDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			dec := json.NewDecoder(r.Body)
+			raw := json.RawMessage{}
+			if err := dec.Decode(&raw); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(fn(raw))
+		})
+	}
+
+	handle(plugin.MethodMount, func(body []byte) interface{} {
+		req := struct {
+			VolumeID  api.VolumeID `json:"volume_id"`
+			MountPath string       `json:"mount_path"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return errResp(err)
+		}
+		return errResp(drv.Mount(req.VolumeID, req.MountPath))
+	})
+
+	handle(plugin.MethodUnmount, func(body []byte) interface{} {
+		req := struct {
+			VolumeID  api.VolumeID `json:"volume_id"`
+			MountPath string       `json:"mount_path"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return errResp(err)
+		}
+		return errResp(drv.Unmount(req.VolumeID, req.MountPath))
+	})
+
+	handle(plugin.MethodDelete, func(body []byte) interface{} {
+		req := struct {
+			VolumeID api.VolumeID `json:"volume_id"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return errResp(err)
+		}
+		return errResp(drv.Delete(req.VolumeID))
+	})
+
+	handle(plugin.MethodStats, func(body []byte) interface{} {
+		req := struct {
+			VolumeID api.VolumeID `json:"volume_id"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		stats, err := drv.Stats(req.VolumeID)
+		resp := map[string]interface{}{"stats": stats}
+		if err != nil {
+			resp["error"] = err.Error()
+		}
+		return resp
+	})
+
+	// Create, Snapshot and the remaining methods are not handled here, and
+	// the mux is not exposed, so a caller of Serve cannot add them.
+
+	return http.Serve(ln, mux)
+}
+
+func errResp(err error) map[string]string {
+	if err == nil {
+		return map[string]string{}
+	}
+	return map[string]string{"error": err.Error()}
+}
@@ -0,0 +1,154 @@
+// Package volume defines the interface every openstorage volume driver
+// implements, and the registry drivers are looked up through.
+package volume
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/libopenstorage/openstorage/api"
+)
+
+// DriverType identifies the class of storage a driver provides.
+type DriverType int
+
+const (
+	TypeBlockDriver DriverType = iota
+	TypeFileDriver
+	// TypePlugin marks a driver dispatched to an out-of-process plugin over
+	// volume/plugin rather than one compiled into this binary.
+	TypePlugin
+)
+
+// DriverParams are the driver-specific parameters passed to an InitFunc,
+// e.g. the NFS driver's "uri".
+type DriverParams map[string]string
+
+// InitFunc constructs a VolumeDriver instance from its parameters. Drivers
+// register one via Register.
+type InitFunc func(params DriverParams) (VolumeDriver, error)
+
+var (
+	// ErrNotSupported is returned by a method a given driver does not
+	// implement.
+	ErrNotSupported = errors.New("Not supported")
+
+	// ErrExist is returned by Register when a driver name is already taken.
+	ErrExist = errors.New("Driver already registered")
+
+	// ErrNotFound is returned by Get when no instance has been created for
+	// a name yet.
+	ErrNotFound = errors.New("Driver not found")
+)
+
+// VolumeDriver is the interface every openstorage volume driver -- built-in
+// or plugged in over volume/plugin -- implements.
+type VolumeDriver interface {
+	// String returns the name the driver was registered under.
+	String() string
+
+	Create(locator api.VolumeLocator, opt *api.CreateOptions, spec *api.VolumeSpec) (api.VolumeID, error)
+	Delete(volumeID api.VolumeID) error
+
+	Mount(volumeID api.VolumeID, mountpath string) error
+	Unmount(volumeID api.VolumeID, mountpath string) error
+
+	Attach(volumeID api.VolumeID) (string, error)
+	Detach(volumeID api.VolumeID) error
+
+	Inspect(volumeIDs []api.VolumeID) ([]api.Volume, error)
+	Enumerate(locator api.VolumeLocator, labels api.Labels) ([]api.Volume, error)
+
+	Snapshot(volumeID api.VolumeID, labels api.Labels) (api.SnapID, error)
+	SnapDelete(snapID api.SnapID) error
+	SnapInspect(snapID []api.SnapID) ([]api.VolumeSnap, error)
+	SnapEnumerate(locator api.VolumeLocator, labels api.Labels) ([]api.VolumeSnap, error)
+	RestoreSnapshot(snapID api.SnapID, newVolumeID api.VolumeID) error
+
+	Stats(volumeID api.VolumeID) (api.VolumeStats, error)
+	Alerts(volumeID api.VolumeID) (api.VolumeAlerts, error)
+
+	// Refresh reconciles whatever this driver persisted about its volumes
+	// with the ground truth it runs on top of (e.g. the kernel's mount
+	// table), recovering from a crash or restart. New does not call it; a
+	// driver that needs it runs it from its own InitFunc, as the NFS driver
+	// does.
+	Refresh() error
+
+	Shutdown()
+}
+
+// DefaultBlockDriver can be embedded by a VolumeDriver implementation to
+// pick up ErrNotSupported defaults for the methods it doesn't implement.
+type DefaultBlockDriver struct{}
+
+func (d *DefaultBlockDriver) Attach(volumeID api.VolumeID) (string, error) {
+	return "", ErrNotSupported
+}
+
+func (d *DefaultBlockDriver) Detach(volumeID api.VolumeID) error {
+	return ErrNotSupported
+}
+
+func (d *DefaultBlockDriver) Refresh() error {
+	return ErrNotSupported
+}
+
+func (d *DefaultBlockDriver) RestoreSnapshot(snapID api.SnapID, newVolumeID api.VolumeID) error {
+	return ErrNotSupported
+}
+
+var (
+	mu        sync.Mutex
+	initFuncs = map[string]InitFunc{}
+	instances = map[string]VolumeDriver{}
+)
+
+// Register associates name with init, so a later New(name, params) call can
+// construct an instance of it. Built-in drivers call this from their own
+// init().
+func Register(name string, dType DriverType, init InitFunc) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := initFuncs[name]; ok {
+		return ErrExist
+	}
+	initFuncs[name] = init
+	return nil
+}
+
+// New constructs and caches the driver registered under name, passing it
+// params.
+func New(name string, params DriverParams) (VolumeDriver, error) {
+	mu.Lock()
+	init, ok := initFuncs[name]
+	mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	driver, err := init(params)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	instances[name] = driver
+	mu.Unlock()
+
+	return driver, nil
+}
+
+// Get returns the already-constructed instance of the driver registered
+// under name (i.e. one New has already been called for).
+func Get(name string) (VolumeDriver, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	driver, ok := instances[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return driver, nil
+}